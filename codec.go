@@ -0,0 +1,98 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// Codec knows how to unmarshal a particular config file format.
+type Codec interface {
+	// Unmarshal decodes b into the value pointed to by v.
+	Unmarshal(b []byte, v interface{}) error
+	// Extensions lists the file extensions (without the leading dot,
+	// e.g. "yaml") this codec handles.
+	Extensions() []string
+}
+
+// StrictCodec is implemented by codecs that can reject unknown
+// fields. Strict mode uses it when available, and falls back to
+// plain Unmarshal otherwise.
+type StrictCodec interface {
+	Codec
+	UnmarshalStrict(b []byte, v interface{}) error
+}
+
+// RegisterCodec adds c ahead of the built-in codecs, so tryLoad
+// probes its extensions first and loadPath prefers it for any
+// extension it claims.
+func RegisterCodec(c Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs = append([]Codec{c}, codecs...)
+}
+
+var (
+	codecsMu sync.Mutex
+	codecs   = []Codec{yamlCodec{}, jsonCodec{}, tomlCodec{}}
+)
+
+// codecFor returns the registered codec that claims ext, or nil if
+// none does.
+func codecFor(ext string) Codec {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	for _, c := range codecs {
+		for _, e := range c.Extensions() {
+			if e == ext {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// candidateExtensions returns every registered codec's extensions, in
+// registration order, for building candidate file names.
+func candidateExtensions() []string {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	var exts []string
+	for _, c := range codecs {
+		exts = append(exts, c.Extensions()...)
+	}
+	return exts
+}
+
+// fileExt returns the extension of name, without the leading dot.
+func fileExt(name string) string {
+	return strings.TrimPrefix(filepath.Ext(name), ".")
+}
+
+type yamlCodec struct{}
+
+func (yamlCodec) Unmarshal(b []byte, v interface{}) error { return yaml.Unmarshal(b, v) }
+func (yamlCodec) UnmarshalStrict(b []byte, v interface{}) error {
+	return yaml.UnmarshalStrict(b, v)
+}
+func (yamlCodec) Extensions() []string { return []string{"yaml", "yml"} }
+
+type jsonCodec struct{}
+
+func (jsonCodec) Unmarshal(b []byte, v interface{}) error { return json.Unmarshal(b, v) }
+func (jsonCodec) UnmarshalStrict(b []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+func (jsonCodec) Extensions() []string { return []string{"json"} }
+
+type tomlCodec struct{}
+
+func (tomlCodec) Unmarshal(b []byte, v interface{}) error { return toml.Unmarshal(b, v) }
+func (tomlCodec) Extensions() []string                    { return []string{"toml"} }
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"sync"
+	"testing"
+)
+
+type upperCodec struct{}
+
+func (upperCodec) Unmarshal(b []byte, v interface{}) error { return nil }
+func (upperCodec) Extensions() []string                    { return []string{"upper"} }
+
+func TestCodecFor(t *testing.T) {
+	if _, ok := codecFor("yaml").(yamlCodec); !ok {
+		t.Errorf("codecFor(%q) = %T, want yamlCodec", "yaml", codecFor("yaml"))
+	}
+	if codecFor("does-not-exist") != nil {
+		t.Errorf("codecFor(%q) = %v, want nil", "does-not-exist", codecFor("does-not-exist"))
+	}
+}
+
+func TestRegisterCodecTakesPrecedence(t *testing.T) {
+	RegisterCodec(upperCodec{})
+	if _, ok := codecFor("upper").(upperCodec); !ok {
+		t.Fatalf("codecFor(%q) didn't return the registered codec", "upper")
+	}
+	if exts := candidateExtensions(); len(exts) == 0 || exts[0] != "upper" {
+		t.Errorf("candidateExtensions() = %v, want the registered codec's %q first", exts, "upper")
+	}
+}
+
+func TestCandidateNamesRace(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			RegisterCodec(upperCodec{})
+		}()
+		go func() {
+			defer wg.Done()
+			_ = candidateNames("config")
+		}()
+	}
+	wg.Wait()
+}
@@ -1,23 +1,32 @@
-// Package config provides a wrapper around YAML configs.
+// Package config provides a wrapper around YAML, JSON, and TOML
+// configs, plus any other format added via RegisterCodec.
 //
-// The default name is config.yaml, with an optional overrides.yaml
-// file, for local overrides.
+// ConfigName and OverridesName are base names, probed against each
+// registered codec's extensions in turn (config.yaml, config.yml,
+// config.json, config.toml, ...), so the default behavior for existing YAML-based
+// callers is unchanged. LoadDir additionally supports a config.d
+// drop-in directory for modular overrides, and ConfigName,
+// OverridesName, and Sources may all name remote (http(s):// etc.)
+// sources in addition to local paths. LoadWithOptions accepts
+// per-call Options, such as enabling ${VAR} environment variable
+// expansion or Strict validation of required fields and JSON Schema.
+// Watch turns the one-shot loader into a subsystem for long-running
+// daemons by reloading on filesystem changes.
 package config
 
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strings"
-
-	"gopkg.in/yaml.v2"
 )
 
 var (
-	ConfigName         = "config.yaml"    // name of YAML config file
-	OverridesName      = "overrides.yaml" // name of optional YAML config holding local overrides
-	BasePath           = "."              // where to start looking for configs; relative to importing code
-	MaxSteps      uint = 5                // maximum number of directories to step up while looking for configs
+	ConfigName         = "config"    // base name of the config file, probed against each codec's extensions
+	OverridesName      = "overrides" // base name of the optional config holding local overrides
+	BasePath           = "."         // where to start looking for configs; relative to importing code
+	MaxSteps      uint = 5           // maximum number of directories to step up while looking for configs
 
 )
 
@@ -35,7 +44,13 @@ func MustLoad(v interface{}) {
 // Load parses the YAML-encoded config and stores the result in the
 // value pointed to by v.
 func Load(v interface{}) error {
-	err := tryLoad(ConfigName, v)
+	return load(v, nil)
+}
+
+// load is the shared implementation behind Load and LoadWithOptions.
+// opts is nil when the package-level defaults should apply.
+func load(v interface{}, opts *Options) error {
+	err := tryLoad(ConfigName, v, opts)
 	if err != nil {
 		return err
 	}
@@ -44,41 +59,146 @@ func Load(v interface{}) error {
 	// INFO message at this point to alert the caller that overrides
 	// file is missing (to make the feature more discoverable), but we
 	// can't use glog in case we're called from AppEngine.
-	_ = tryLoad(OverridesName, v)
+	_ = tryLoad(OverridesName, v, opts)
+	for _, src := range Sources {
+		if err := loadPath(src, v, opts); err != nil {
+			return fmt.Errorf("couldn't load source %q: %v", src, err)
+		}
+	}
+	if opts != nil && opts.Strict {
+		return Validate(v, *opts)
+	}
 	return nil
 }
 
-// tryLoad parses the YAML-encoded config in file name and stores the
-// result in the value pointed to by v.
+// tryLoad parses the config named name and stores the result in the
+// value pointed to by v.
 //
-// tryLoad steps up one directory level at a time, at most MaxSteps
-// number of times, until the named config file is found.
-func tryLoad(name string, v interface{}) error {
+// If name is a remote source (see isRemote), it's fetched directly.
+// If name already carries an extension claimed by a registered codec
+// (e.g. "config.yaml"), that exact file is searched for. Otherwise
+// name is treated as a base name and tried with each registered
+// codec's extensions in turn (e.g. "config.yaml", then "config.yml",
+// then "config.json", then "config.toml"). Either way, tryLoad steps up one directory
+// level at a time, at most MaxSteps number of times, until a matching
+// file is found.
+func tryLoad(name string, v interface{}, opts *Options) error {
+	if isRemote(name) {
+		return loadPath(name, v, opts)
+	}
+	if codecFor(fileExt(name)) != nil {
+		return tryLoadExact(name, v, opts)
+	}
+	var err error
+	for _, candidate := range candidateNames(name) {
+		if err = tryLoadExact(candidate, v, opts); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("failed to find a valid %q: %v", name, err)
+}
+
+// candidateNames returns name with each registered codec's extensions
+// appended, in registration order.
+func candidateNames(name string) []string {
+	var names []string
+	for _, ext := range candidateExtensions() {
+		names = append(names, name+"."+ext)
+	}
+	return names
+}
+
+// tryLoadExact steps up one directory level at a time, at most
+// MaxSteps number of times, until the exactly-named local config file
+// is found and successfully loaded.
+func tryLoadExact(name string, v interface{}, opts *Options) error {
 	var err error
 	tries := uint(0)
 	path := filepath.Join(BasePath, name)
 	for tries <= MaxSteps {
-		err := loadPath(path, v)
+		err = loadPath(path, v, opts)
 		if err == nil {
 			return nil
 		}
 		path = filepath.Join(BasePath, strings.Repeat("../", int(tries+1)), name)
 		tries += 1
 	}
-	return fmt.Errorf("failed to find a valid %q: %v", name, err)
+	return err
 }
 
-// loadPath parses the YAML-encoded config at path and stores the
-// result in the value pointed to by v.
-func loadPath(path string, v interface{}) error {
-	b, err := ioutil.ReadFile(path)
+// resolvedConfigPath returns the actual local path that tryLoad would
+// currently load for name, using the same directory-stepping and
+// codec-extension probing, or "" if no matching file exists. name is
+// assumed to not be a remote source.
+func resolvedConfigPath(name string) string {
+	candidates := []string{name}
+	if codecFor(fileExt(name)) == nil {
+		candidates = candidateNames(name)
+	}
+	for _, candidate := range candidates {
+		tries := uint(0)
+		path := filepath.Join(BasePath, candidate)
+		for tries <= MaxSteps {
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+			path = filepath.Join(BasePath, strings.Repeat("../", int(tries+1)), candidate)
+			tries += 1
+		}
+	}
+	return ""
+}
+
+// loadPath parses the config at path, dispatching to the Codec
+// registered for its extension (falling back to YAML), and stores
+// the result in the value pointed to by v.
+//
+// If path is a remote source, it's fetched (and checksum-verified)
+// instead of read from the local filesystem. opts is nil when the
+// package-level defaults should apply.
+func loadPath(path string, v interface{}, opts *Options) error {
+	var b []byte
+	var err error
+	if isRemote(path) {
+		b, err = fetch(path)
+	} else {
+		b, err = ioutil.ReadFile(path)
+	}
 	if err != nil {
 		return fmt.Errorf("couldn't read config: %v", err)
 	}
 
-	err = yaml.Unmarshal(b, v)
+	c := codecFor(fileExt(path))
+	if c == nil {
+		c = yamlCodec{}
+	}
+
+	// expandEnv round-trips through a YAML tree, so it's only safe to
+	// apply ahead of the YAML codec; running it ahead of JSON or TOML
+	// would re-serialize the document as YAML and corrupt it.
+	if _, isYAML := c.(yamlCodec); isYAML && enableEnvExpansion(opts) {
+		b, err = expandEnv(b)
+		if err != nil {
+			return fmt.Errorf("couldn't expand env vars: %v", err)
+		}
+	}
+
+	if sc, ok := c.(StrictCodec); ok && opts != nil && opts.Strict {
+		err = sc.UnmarshalStrict(b, v)
+	} else {
+		err = c.Unmarshal(b, v)
+	}
 	if err != nil {
 		return fmt.Errorf("couldn't unmarshal config: %v", err)
 	}
 	return nil
 }
+
+// enableEnvExpansion reports whether env var expansion should run for
+// this call, honoring an opts override of the package-level default.
+func enableEnvExpansion(opts *Options) bool {
+	if opts != nil {
+		return opts.EnableEnvExpansion
+	}
+	return EnableEnvExpansion
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"gopkg.in/yaml.v2"
+)
+
+// DirName is the name of the optional drop-in directory holding
+// additional config fragments, layered on top of ConfigName and
+// OverridesName.
+var DirName = "config.d"
+
+// MustLoadDir is like MustLoad, but additionally applies any
+// fragments found in the DirName directory.
+//
+// MustLoadDir panics if the config can't be loaded.
+func MustLoadDir(v interface{}) {
+	err := LoadDir(v)
+	if err != nil {
+		panic(fmt.Errorf("FATAL: %v\n", err))
+	}
+}
+
+// LoadDir is like Load, but additionally layers any fragments found
+// in the sibling DirName directory on top of the base config, applied
+// in lexical order by file name.
+//
+// Each fragment may be a full YAML/JSON document, which is
+// deep-merged into the running config; a JSON Patch (RFC 6902)
+// document, when the file name ends in ".patch.json" or
+// ".patch.yaml"; or an RFC 7396 JSON Merge Patch document, when it
+// ends in ".merge.json" or ".merge.yaml". This lets operators split
+// secrets from the main config and ship modular overrides without
+// editing config.yaml itself.
+func LoadDir(v interface{}) error {
+	if err := Load(v); err != nil {
+		return err
+	}
+	dir := filepath.Join(BasePath, DirName)
+	names, err := fragmentNames(dir)
+	if err != nil {
+		// Note: as with overrides.yaml, a missing config.d is not an
+		// error.
+		return nil
+	}
+	b, err := toJSON(v)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal config for merging: %v", err)
+	}
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		frag, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("couldn't read %q: %v", path, err)
+		}
+		b, err = applyFragment(b, name, frag)
+		if err != nil {
+			return fmt.Errorf("couldn't apply %q: %v", path, err)
+		}
+	}
+	// toJSON produced b's keys from v's yaml tags (via yaml.Marshal), so
+	// the merged document must be unmarshaled the same way, not with
+	// encoding/json, which would instead resolve json tags/Go field
+	// names and silently drop any field whose yaml tag isn't just a
+	// case-fold of its Go name.
+	y, err := jsonToYAML(b)
+	if err != nil {
+		return fmt.Errorf("couldn't convert merged config to YAML: %v", err)
+	}
+	return yaml.Unmarshal(y, v)
+}
+
+// fragmentNames returns the names of the regular files in dir, sorted
+// lexically.
+func fragmentNames(dir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// applyFragment applies the fragment named name, whose raw bytes are
+// frag, on top of the JSON document b, and returns the resulting JSON
+// document.
+func applyFragment(b []byte, name string, frag []byte) ([]byte, error) {
+	switch {
+	case strings.HasSuffix(name, ".patch.json"):
+		return applyJSONPatch(b, frag)
+	case strings.HasSuffix(name, ".patch.yaml"):
+		j, err := yamlToJSON(frag)
+		if err != nil {
+			return nil, err
+		}
+		return applyJSONPatch(b, j)
+	case strings.HasSuffix(name, ".merge.json"):
+		return jsonpatch.MergePatch(b, frag)
+	case strings.HasSuffix(name, ".merge.yaml"):
+		j, err := yamlToJSON(frag)
+		if err != nil {
+			return nil, err
+		}
+		return jsonpatch.MergePatch(b, j)
+	default:
+		// A plain YAML/JSON document is deep-merged, which RFC 7396
+		// semantics already give us for object fragments.
+		j, err := yamlToJSON(frag)
+		if err != nil {
+			return nil, err
+		}
+		return jsonpatch.MergePatch(b, j)
+	}
+}
+
+// applyJSONPatch applies the RFC 6902 JSON Patch document patch to b.
+func applyJSONPatch(b, patch []byte) ([]byte, error) {
+	p, err := jsonpatch.DecodePatch(patch)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode JSON Patch: %v", err)
+	}
+	return p.Apply(b)
+}
+
+// toJSON marshals v, which was previously populated by Load, to JSON,
+// so it can be merged against subsequent drop-in fragments.
+func toJSON(v interface{}) ([]byte, error) {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return yamlToJSON(b)
+}
+
+// yamlToJSON converts YAML-encoded bytes to the equivalent JSON.
+// gopkg.in/yaml.v2 decodes mappings as map[interface{}]interface{},
+// which encoding/json can't marshal directly, so the tree is cleaned
+// up first.
+func yamlToJSON(b []byte) ([]byte, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	return json.Marshal(cleanupYAML(raw))
+}
+
+// jsonToYAML converts JSON-encoded bytes to the equivalent YAML, the
+// inverse of yamlToJSON, so a document produced as JSON for merging
+// can be unmarshaled the same way it was marshaled.
+func jsonToYAML(b []byte) ([]byte, error) {
+	var raw interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(raw)
+}
+
+// cleanupYAML recursively converts the map[interface{}]interface{}
+// values produced by yaml.v2 into map[string]interface{}, so the
+// result can be marshaled by encoding/json.
+func cleanupYAML(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprintf("%v", k)] = cleanupYAML(val)
+		}
+		return m
+	case []interface{}:
+		for i, e := range v {
+			v[i] = cleanupYAML(e)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type dirTestConfig struct {
+	Name string
+	Port int
+	Tags []string
+}
+
+func withTestConfigDir(t *testing.T, base string, dropIns map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "config.yaml"), []byte(base), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if len(dropIns) > 0 {
+		confD := filepath.Join(dir, "config.d")
+		if err := os.MkdirAll(confD, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		for name, content := range dropIns {
+			if err := ioutil.WriteFile(filepath.Join(confD, name), []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return dir
+}
+
+func TestLoadDirPlainDocMerges(t *testing.T) {
+	dir := withTestConfigDir(t, "name: svc\nport: 8080\n", map[string]string{
+		"10-port.yaml": "port: 9090\n",
+	})
+	oldBase, oldConfig := BasePath, ConfigName
+	defer func() { BasePath, ConfigName = oldBase, oldConfig }()
+	BasePath, ConfigName = dir, "config"
+
+	var v dirTestConfig
+	if err := LoadDir(&v); err != nil {
+		t.Fatalf("LoadDir() = %v", err)
+	}
+	if v.Name != "svc" || v.Port != 9090 {
+		t.Errorf("LoadDir() = %+v, want {Name:svc Port:9090}", v)
+	}
+}
+
+func TestLoadDirMergePatch(t *testing.T) {
+	dir := withTestConfigDir(t, "name: svc\nport: 8080\n", map[string]string{
+		"10-port.merge.json": `{"port": 9090}`,
+	})
+	oldBase, oldConfig := BasePath, ConfigName
+	defer func() { BasePath, ConfigName = oldBase, oldConfig }()
+	BasePath, ConfigName = dir, "config"
+
+	var v dirTestConfig
+	if err := LoadDir(&v); err != nil {
+		t.Fatalf("LoadDir() = %v", err)
+	}
+	if v.Port != 9090 {
+		t.Errorf("LoadDir().Port = %d, want 9090", v.Port)
+	}
+}
+
+func TestLoadDirJSONPatch(t *testing.T) {
+	dir := withTestConfigDir(t, "name: svc\nport: 8080\ntags: [a, b]\n", map[string]string{
+		"10-tags.patch.json": `[{"op": "add", "path": "/tags/-", "value": "c"}]`,
+	})
+	oldBase, oldConfig := BasePath, ConfigName
+	defer func() { BasePath, ConfigName = oldBase, oldConfig }()
+	BasePath, ConfigName = dir, "config"
+
+	var v dirTestConfig
+	if err := LoadDir(&v); err != nil {
+		t.Fatalf("LoadDir() = %v", err)
+	}
+	want := []string{"a", "b", "c"}
+	if len(v.Tags) != len(want) {
+		t.Fatalf("LoadDir().Tags = %v, want %v", v.Tags, want)
+	}
+	for i := range want {
+		if v.Tags[i] != want[i] {
+			t.Errorf("LoadDir().Tags = %v, want %v", v.Tags, want)
+			break
+		}
+	}
+}
+
+func TestLoadDirAppliesInLexicalOrder(t *testing.T) {
+	dir := withTestConfigDir(t, "port: 1\n", map[string]string{
+		"10-a.yaml": "port: 2\n",
+		"20-b.yaml": "port: 3\n",
+	})
+	oldBase, oldConfig := BasePath, ConfigName
+	defer func() { BasePath, ConfigName = oldBase, oldConfig }()
+	BasePath, ConfigName = dir, "config"
+
+	var v dirTestConfig
+	if err := LoadDir(&v); err != nil {
+		t.Fatalf("LoadDir() = %v", err)
+	}
+	// 20-b.yaml applies after 10-a.yaml lexically, so its value wins.
+	if v.Port != 3 {
+		t.Errorf("LoadDir().Port = %d, want 3 (lexically-last fragment)", v.Port)
+	}
+}
+
+type dirTestTaggedConfig struct {
+	DBHost string `yaml:"db_host"`
+}
+
+// TestLoadDirHonorsYAMLTagsThatArentJustCaseFolds guards against the
+// merge round trip resolving the final unmarshal via json tags/Go
+// field names instead of the yaml tags used to build the
+// intermediate JSON, which would silently drop overrides for any
+// field whose yaml tag isn't just a case-fold of its Go name (e.g.
+// the common `yaml:"db_host"` convention).
+func TestLoadDirHonorsYAMLTagsThatArentJustCaseFolds(t *testing.T) {
+	dir := withTestConfigDir(t, "db_host: base\n", map[string]string{
+		"10-override.yaml": "db_host: overridden\n",
+	})
+	oldBase, oldConfig := BasePath, ConfigName
+	defer func() { BasePath, ConfigName = oldBase, oldConfig }()
+	BasePath, ConfigName = dir, "config"
+
+	var v dirTestTaggedConfig
+	if err := LoadDir(&v); err != nil {
+		t.Fatalf("LoadDir() = %v", err)
+	}
+	if v.DBHost != "overridden" {
+		t.Errorf("LoadDir().DBHost = %q, want %q", v.DBHost, "overridden")
+	}
+}
+
+func TestLoadDirWithoutDropInsIsNotAnError(t *testing.T) {
+	dir := withTestConfigDir(t, "port: 1\n", nil)
+	oldBase, oldConfig := BasePath, ConfigName
+	defer func() { BasePath, ConfigName = oldBase, oldConfig }()
+	BasePath, ConfigName = dir, "config"
+
+	var v dirTestConfig
+	if err := LoadDir(&v); err != nil {
+		t.Fatalf("LoadDir() = %v, want nil when config.d is absent", err)
+	}
+	if v.Port != 1 {
+		t.Errorf("LoadDir().Port = %d, want 1", v.Port)
+	}
+}
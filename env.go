@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v2"
+)
+
+// EnableEnvExpansion, if true, expands ${VAR}, ${VAR:-default},
+// ${VAR:?error message}, and $VAR references inside string scalars
+// before a YAML config is unmarshaled; JSON and TOML configs are not
+// expanded. Use Options.EnableEnvExpansion via
+// LoadWithOptions to override this for a single call.
+var EnableEnvExpansion = false
+
+// MissingEnvError is returned when a required environment variable
+// (the "${VAR:?message}" form) is unset.
+type MissingEnvError struct {
+	Var     string
+	Path    string
+	Message string
+}
+
+func (e *MissingEnvError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("missing required env var %q at %s: %s", e.Var, e.Path, e.Message)
+	}
+	return fmt.Sprintf("missing required env var %q at %s", e.Var, e.Path)
+}
+
+// envPattern matches "${VAR}", "${VAR:-default}", "${VAR:?message}",
+// and "$VAR" references.
+var envPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-|:\?)?([^}]*)\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// expandEnv decodes the YAML document b into a generic tree, expands
+// env var references in every string scalar, and re-encodes it back
+// to YAML bytes for the real unmarshal step.
+func expandEnv(b []byte) ([]byte, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	expanded, err := expandEnvValue(raw, "$")
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(expanded)
+}
+
+// expandEnvValue walks v, expanding string scalars, and reports path
+// as the YAML path to v for use in MissingEnvError.
+func expandEnvValue(v interface{}, path string) (interface{}, error) {
+	switch v := v.(type) {
+	case string:
+		return expandEnvString(v, path)
+	case map[interface{}]interface{}:
+		for k, val := range v {
+			sub, err := expandEnvValue(val, fmt.Sprintf("%s.%v", path, k))
+			if err != nil {
+				return nil, err
+			}
+			v[k] = sub
+		}
+		return v, nil
+	case []interface{}:
+		for i, e := range v {
+			sub, err := expandEnvValue(e, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			v[i] = sub
+		}
+		return v, nil
+	default:
+		return v, nil
+	}
+}
+
+// expandEnvString expands every env var reference in s, reporting
+// path in any returned MissingEnvError.
+func expandEnvString(s, path string) (string, error) {
+	var missing *MissingEnvError
+	out := envPattern.ReplaceAllStringFunc(s, func(match string) string {
+		groups := envPattern.FindStringSubmatch(match)
+		name, modifier, rest, bare := groups[1], groups[2], groups[3], groups[4]
+		if name == "" {
+			name = bare
+		}
+		val, ok := os.LookupEnv(name)
+		if ok {
+			return val
+		}
+		switch modifier {
+		case ":-":
+			return rest
+		case ":?":
+			if missing == nil {
+				missing = &MissingEnvError{Var: name, Path: path, Message: rest}
+			}
+			return match
+		default:
+			return ""
+		}
+	})
+	if missing != nil {
+		return "", missing
+	}
+	return out, nil
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExpandEnvString(t *testing.T) {
+	os.Setenv("CONFIG_TEST_HOST", "db.internal")
+	defer os.Unsetenv("CONFIG_TEST_HOST")
+	os.Unsetenv("CONFIG_TEST_MISSING")
+	os.Unsetenv("CONFIG_TEST_DEFAULTED")
+
+	cases := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"${CONFIG_TEST_HOST}", "db.internal", false},
+		{"$CONFIG_TEST_HOST", "db.internal", false},
+		{"${CONFIG_TEST_DEFAULTED:-localhost}", "localhost", false},
+		{"${CONFIG_TEST_MISSING:?must be set}", "", true},
+	}
+	for _, c := range cases {
+		got, err := expandEnvString(c.in, "$.field")
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("expandEnvString(%q) = nil error, want MissingEnvError", c.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("expandEnvString(%q) = %v", c.in, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("expandEnvString(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestExpandEnvMissingVarReportsPath(t *testing.T) {
+	os.Unsetenv("CONFIG_TEST_MISSING")
+	b := []byte("db:\n  host: ${CONFIG_TEST_MISSING:?must be set}\n")
+	_, err := expandEnv(b)
+	me, ok := err.(*MissingEnvError)
+	if !ok {
+		t.Fatalf("expandEnv error = %T(%v), want *MissingEnvError", err, err)
+	}
+	if me.Var != "CONFIG_TEST_MISSING" {
+		t.Errorf("MissingEnvError.Var = %q, want %q", me.Var, "CONFIG_TEST_MISSING")
+	}
+	if me.Path != "$.db.host" {
+		t.Errorf("MissingEnvError.Path = %q, want %q", me.Path, "$.db.host")
+	}
+}
+
+// TestLoadPathSkipsExpansionForTOML guards against env-expansion
+// round-tripping a TOML document through a YAML tree, which would
+// corrupt it.
+func TestLoadPathSkipsExpansionForTOML(t *testing.T) {
+	os.Setenv("CONFIG_TEST_HOST", "db.internal")
+	defer os.Unsetenv("CONFIG_TEST_HOST")
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	toml := "name = \"svc\"\nport = 8080\n"
+	if err := ioutil.WriteFile(path, []byte(toml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var v struct {
+		Name string
+		Port int
+	}
+	opts := &Options{EnableEnvExpansion: true}
+	if err := loadPath(path, &v, opts); err != nil {
+		t.Fatalf("loadPath(%q) = %v", path, err)
+	}
+	if v.Name != "svc" || v.Port != 8080 {
+		t.Errorf("loadPath(%q) = %+v, want {Name:svc Port:8080}", path, v)
+	}
+}
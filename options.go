@@ -0,0 +1,27 @@
+package config
+
+// Options configures a single LoadWithOptions call, overriding the
+// package-level defaults for that call only.
+type Options struct {
+	// EnableEnvExpansion, if true, expands ${VAR} references in string
+	// scalars of YAML configs before unmarshaling. It replaces the
+	// package-level EnableEnvExpansion for this call, so leaving it
+	// false disables expansion even when EnableEnvExpansion is true.
+	EnableEnvExpansion bool
+
+	// Strict, if true, rejects unknown fields for codecs implementing
+	// StrictCodec (the built-in YAML and JSON codecs; unknown TOML
+	// fields are not rejected) and, once loading succeeds, runs
+	// Validate against the result.
+	Strict bool
+
+	// Schema, if set, is a JSON Schema document that the loaded config
+	// must satisfy when Strict is true.
+	Schema []byte
+}
+
+// LoadWithOptions is like Load, but applies opts for this call
+// instead of the package-level defaults.
+func LoadWithOptions(v interface{}, opts Options) error {
+	return load(v, &opts)
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsRemote(t *testing.T) {
+	cases := map[string]bool{
+		"config.yaml":                 false,
+		"../config.yaml":              false,
+		"http://example.com/c.yaml":   true,
+		"https://example.com/c.yaml":  true,
+		"file://rel/c.yaml":           true,
+		"file:///abs/c.yaml":          true,
+		"hf://org/repo/c.yaml":        true,
+	}
+	for name, want := range cases {
+		if got := isRemote(name); got != want {
+			t.Errorf("isRemote(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestFetchOnceFileScheme(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "c.yaml"), []byte("a: 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Absolute form: file:///abs/path.
+	b, err := fetchOnce("file://" + dir + "/c.yaml")
+	if err != nil {
+		t.Fatalf("fetchOnce(abs) = %v", err)
+	}
+	if string(b) != "a: 1\n" {
+		t.Errorf("fetchOnce(abs) = %q, want %q", b, "a: 1\n")
+	}
+
+	// Relative form: file://rel/path, where url.Parse puts the first
+	// segment in u.Host rather than u.Path.
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rel, err := filepath.Rel(cwd, filepath.Join(dir, "c.yaml"))
+	if err != nil {
+		t.Skip("temp dir not relative to cwd")
+	}
+	b, err = fetchOnce("file://" + rel)
+	if err != nil {
+		t.Fatalf("fetchOnce(rel) = %v", err)
+	}
+	if string(b) != "a: 1\n" {
+		t.Errorf("fetchOnce(rel) = %q, want %q", b, "a: 1\n")
+	}
+}
+
+func TestDefaultCacheDir(t *testing.T) {
+	oldXDG, hadXDG := os.LookupEnv("XDG_CACHE_HOME")
+	oldHome := os.Getenv("HOME")
+	defer func() {
+		if hadXDG {
+			os.Setenv("XDG_CACHE_HOME", oldXDG)
+		} else {
+			os.Unsetenv("XDG_CACHE_HOME")
+		}
+		os.Setenv("HOME", oldHome)
+	}()
+
+	os.Setenv("XDG_CACHE_HOME", "/xdg-cache")
+	if got, want := defaultCacheDir(), filepath.Join("/xdg-cache", "hkjn-config"); got != want {
+		t.Errorf("defaultCacheDir() = %q, want %q", got, want)
+	}
+
+	os.Unsetenv("XDG_CACHE_HOME")
+	os.Setenv("HOME", "/home/user")
+	if got, want := defaultCacheDir(), filepath.Join("/home/user", ".cache", "hkjn-config"); got != want {
+		t.Errorf("defaultCacheDir() = %q, want %q", got, want)
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	want := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	if got := checksum([]byte("hello")); got != want {
+		t.Errorf("checksum(%q) = %q, want %q", "hello", got, want)
+	}
+}
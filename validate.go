@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// ValidationError reports every problem found while validating a
+// config, rather than stopping at the first one, so callers can
+// render them all at once.
+type ValidationError struct {
+	Problems []Problem
+}
+
+// Problem is a single validation failure.
+type Problem struct {
+	Path    string // the field path the problem was found at
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	msg := fmt.Sprintf("%d config validation problem(s):", len(e.Problems))
+	for _, p := range e.Problems {
+		msg += fmt.Sprintf("\n  %s: %s", p.Path, p.Message)
+	}
+	return msg
+}
+
+// Validate checks v, which must already be populated (e.g. by Load),
+// against its `config:"required"` struct tags and, if opts.Schema is
+// set, against the supplied JSON Schema. It does no file I/O, so
+// servers can validate a candidate config before swapping it in.
+func Validate(v interface{}, opts Options) error {
+	problems := checkRequired(reflect.ValueOf(v), "")
+	if len(opts.Schema) > 0 {
+		schemaProblems, err := checkSchema(v, opts.Schema)
+		if err != nil {
+			return err
+		}
+		problems = append(problems, schemaProblems...)
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// checkRequired recursively looks for fields tagged
+// `config:"required"` that were left at their zero value. path is
+// built from yaml field names (see yamlFieldName), so it lines up
+// with the dotted paths checkSchema reports for the same config.
+func checkRequired(rv reflect.Value, path string) []Problem {
+	var problems []Problem
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return problems
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return problems
+	}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		fv := rv.Field(i)
+		name := yamlFieldName(f)
+		if name == "-" {
+			continue
+		}
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+		if f.Tag.Get("config") == "required" && fv.IsZero() {
+			problems = append(problems, Problem{Path: fieldPath, Message: "required field is missing"})
+		}
+		problems = append(problems, checkRequired(fv, fieldPath)...)
+	}
+	return problems
+}
+
+// yamlFieldName returns the key yaml.Marshal would use for f: its
+// yaml tag name if set, otherwise its lowercased Go field name. This
+// mirrors gopkg.in/yaml.v2's own field-naming convention so
+// checkRequired's problem paths use the same naming as checkSchema's,
+// which validates the yaml-tag-keyed JSON produced by toJSON.
+func yamlFieldName(f reflect.StructField) string {
+	tag := f.Tag.Get("yaml")
+	if tag == "" {
+		return strings.ToLower(f.Name)
+	}
+	name := strings.SplitN(tag, ",", 2)[0]
+	if name == "" {
+		return strings.ToLower(f.Name)
+	}
+	return name
+}
+
+// checkSchema validates v, marshaled to JSON, against the given JSON
+// Schema document.
+func checkSchema(v interface{}, schema []byte) ([]Problem, error) {
+	b, err := toJSON(v)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't marshal config for schema validation: %v", err)
+	}
+	result, err := gojsonschema.Validate(
+		gojsonschema.NewBytesLoader(schema),
+		gojsonschema.NewBytesLoader(b),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't validate schema: %v", err)
+	}
+	var problems []Problem
+	for _, e := range result.Errors() {
+		problems = append(problems, Problem{Path: e.Field(), Message: e.Description()})
+	}
+	return problems, nil
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+type validateTestConfig struct {
+	Name   string `config:"required"`
+	DBHost string `yaml:"db_host" config:"required"`
+	Port   int
+}
+
+func TestValidateRequiredField(t *testing.T) {
+	v := validateTestConfig{Port: 8080}
+	err := Validate(&v, Options{})
+	ve, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("Validate() = %T(%v), want *ValidationError", err, err)
+	}
+	if len(ve.Problems) != 2 {
+		t.Fatalf("Validate().Problems = %+v, want 2 problems", ve.Problems)
+	}
+	var gotPaths []string
+	for _, p := range ve.Problems {
+		gotPaths = append(gotPaths, p.Path)
+	}
+	// Paths are yaml-tag-keyed, matching checkSchema's naming, not the
+	// Go field names ("name", "db_host", not "Name", "DBHost").
+	want := map[string]bool{"name": true, "db_host": true}
+	for _, p := range gotPaths {
+		if !want[p] {
+			t.Errorf("Validate().Problems paths = %v, want each to be one of %v", gotPaths, want)
+		}
+	}
+}
+
+func TestValidateRequiredFieldSatisfied(t *testing.T) {
+	v := validateTestConfig{Name: "svc", DBHost: "db.internal", Port: 8080}
+	if err := Validate(&v, Options{}); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	v := validateTestConfig{Name: "svc", DBHost: "db.internal", Port: -1}
+	schema := []byte(`{
+		"type": "object",
+		"properties": {"port": {"type": "integer", "minimum": 0}}
+	}`)
+	err := Validate(&v, Options{Schema: schema})
+	ve, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("Validate() = %T(%v), want *ValidationError", err, err)
+	}
+	if len(ve.Problems) == 0 {
+		t.Errorf("Validate().Problems is empty, want a schema violation for negative port")
+	}
+}
+
+type validateTestNestedConfig struct {
+	DB struct {
+		Host string `yaml:"host" config:"required"`
+	} `yaml:"db"`
+}
+
+// TestValidateRequiredAndSchemaPathsAgree guards against
+// checkRequired and checkSchema reporting the same config field under
+// two different naming conventions ($.DB.Host vs. db.host) within a
+// single ValidationError.
+func TestValidateRequiredAndSchemaPathsAgree(t *testing.T) {
+	var v validateTestNestedConfig
+	schema := []byte(`{
+		"type": "object",
+		"properties": {"db": {"type": "object", "properties": {"host": {"type": "string", "minLength": 1}}}}
+	}`)
+	err := Validate(&v, Options{Schema: schema})
+	ve, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("Validate() = %T(%v), want *ValidationError", err, err)
+	}
+	for _, p := range ve.Problems {
+		if p.Path != "db.host" {
+			t.Errorf("Problem.Path = %q, want %q for both the required-field and schema checks", p.Path, "db.host")
+		}
+	}
+}
+
+func TestValidationErrorMessageListsAllProblems(t *testing.T) {
+	err := &ValidationError{Problems: []Problem{
+		{Path: "$.A", Message: "first"},
+		{Path: "$.B", Message: "second"},
+	}}
+	msg := err.Error()
+	if !strings.Contains(msg, "$.A") || !strings.Contains(msg, "$.B") {
+		t.Errorf("ValidationError.Error() = %q, want it to mention both problems", msg)
+	}
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long Watch waits for a burst of filesystem
+// events (e.g. an editor's write-then-rename) to settle before
+// reloading.
+const watchDebounce = 200 * time.Millisecond
+
+// watchErrors carries errors from failed reloads and from the
+// underlying fsnotify watcher; read it via WatchErrors.
+var watchErrors = make(chan error, 16)
+
+// WatchErrors returns the channel that Watch posts reload and
+// fsnotify errors to.
+func WatchErrors() <-chan error {
+	return watchErrors
+}
+
+// Watch observes config.yaml, overrides.yaml, and, if present, the
+// config.d directory for changes, reloading a fresh value of v's type
+// and invoking onChange whenever they settle. It returns a stop
+// function that halts the watch.
+//
+// Events are debounced by watchDebounce so a burst of writes only
+// triggers a single reload. The new config is swapped in atomically;
+// if onChange returns an error, the previous config is kept and the
+// error is surfaced on the channel returned by WatchErrors, turning
+// the otherwise one-shot loader into a subsystem long-running
+// daemons can use.
+func Watch(v interface{}, onChange func(old, new interface{}) error) (stop func(), err error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create watcher: %v", err)
+	}
+	for _, path := range watchPaths() {
+		if err := watcher.Add(path); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("couldn't watch %q: %v", path, err)
+		}
+	}
+
+	var current atomic.Value
+	current.Store(v)
+	configType := reflect.TypeOf(v).Elem()
+
+	var mu sync.Mutex
+	var debounce *time.Timer
+	done := make(chan struct{})
+
+	reload := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		old := current.Load()
+		next := reflect.New(configType).Interface()
+		if err := loadForWatch()(next); err != nil {
+			watchErrors <- fmt.Errorf("couldn't reload config: %v", err)
+			return
+		}
+		if err := onChange(old, next); err != nil {
+			watchErrors <- fmt.Errorf("rejected reloaded config: %v", err)
+			return
+		}
+		current.Store(next)
+	}
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) == 0 {
+					continue
+				}
+				mu.Lock()
+				if debounce != nil {
+					debounce.Stop()
+				}
+				debounce = time.AfterFunc(watchDebounce, reload)
+				mu.Unlock()
+			case werr, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				watchErrors <- werr
+			}
+		}
+	}()
+
+	stop = func() {
+		close(done)
+		watcher.Close()
+	}
+	return stop, nil
+}
+
+// loadForWatch returns Load or LoadDir, whichever matches how
+// watchPaths decided what to observe: if a config.d directory exists,
+// it's included in the watch list, so reload must go through LoadDir
+// to re-apply its fragments; otherwise a plain Load suffices.
+func loadForWatch() func(interface{}) error {
+	dir := filepath.Join(BasePath, DirName)
+	if _, err := os.Stat(dir); err == nil {
+		return LoadDir
+	}
+	return Load
+}
+
+// watchPaths returns the resolved paths Watch should observe: the
+// base config, the overrides file, and the config.d directory, for
+// whichever of these currently exist. ConfigName and OverridesName
+// are resolved the same way tryLoad resolves them (directory-stepping
+// and codec-extension probing), so a base name like "config" watches
+// the actual "config.yaml" (or "config.json", etc.) on disk.
+func watchPaths() []string {
+	var paths []string
+	for _, name := range []string{ConfigName, OverridesName} {
+		if path := resolvedConfigPath(name); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	dir := filepath.Join(BasePath, DirName)
+	if _, err := os.Stat(dir); err == nil {
+		paths = append(paths, dir)
+	}
+	return paths
+}
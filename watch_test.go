@@ -0,0 +1,128 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestWatchPathsResolvesBaseNames guards against watchPaths treating
+// ConfigName/OverridesName as literal file names; with the extensionless
+// base-name defaults ("config", "overrides"), it must resolve to the
+// actual file on disk (e.g. "config.yaml").
+func TestWatchPathsResolvesBaseNames(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "config.yaml"), []byte("a: 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	oldBase, oldConfig, oldOverrides := BasePath, ConfigName, OverridesName
+	defer func() { BasePath, ConfigName, OverridesName = oldBase, oldConfig, oldOverrides }()
+	BasePath = dir
+	ConfigName = "config"
+	OverridesName = "overrides"
+
+	paths := watchPaths()
+	if len(paths) != 1 {
+		t.Fatalf("watchPaths() = %v, want exactly the resolved config.yaml", paths)
+	}
+	if want := filepath.Join(dir, "config.yaml"); paths[0] != want {
+		t.Errorf("watchPaths()[0] = %q, want %q", paths[0], want)
+	}
+}
+
+func TestWatchReloadsOnChange(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(configPath, []byte("value: 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	oldBase, oldConfig, oldOverrides := BasePath, ConfigName, OverridesName
+	defer func() { BasePath, ConfigName, OverridesName = oldBase, oldConfig, oldOverrides }()
+	BasePath = dir
+	ConfigName = "config"
+	OverridesName = "overrides"
+
+	type cfg struct {
+		Value int
+	}
+	v := &cfg{Value: 1}
+
+	changed := make(chan *cfg, 1)
+	stop, err := Watch(v, func(old, new interface{}) error {
+		changed <- new.(*cfg)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Watch() = %v", err)
+	}
+	defer stop()
+
+	if err := ioutil.WriteFile(configPath, []byte("value: 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-changed:
+		if got.Value != 2 {
+			t.Errorf("reloaded config = %+v, want Value:2", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("onChange was never called after config.yaml changed")
+	}
+}
+
+// TestWatchReloadsConfigDirDropIns guards against reload() falling
+// back to a plain Load when config.d is present, which would discard
+// every drop-in override on the very first reload even though
+// watchPaths is already watching the directory for changes.
+func TestWatchReloadsConfigDirDropIns(t *testing.T) {
+	dir := withTestConfigDir(t, "value: 1\n", map[string]string{
+		"10-override.yaml": "value: 99\n",
+	})
+
+	oldBase, oldConfig, oldOverrides := BasePath, ConfigName, OverridesName
+	defer func() { BasePath, ConfigName, OverridesName = oldBase, oldConfig, oldOverrides }()
+	BasePath = dir
+	ConfigName = "config"
+	OverridesName = "overrides"
+
+	type cfg struct {
+		Value int
+	}
+	v := &cfg{}
+	if err := LoadDir(v); err != nil {
+		t.Fatalf("LoadDir() = %v", err)
+	}
+	if v.Value != 99 {
+		t.Fatalf("LoadDir().Value = %d, want 99", v.Value)
+	}
+
+	changed := make(chan *cfg, 1)
+	stop, err := Watch(v, func(old, new interface{}) error {
+		changed <- new.(*cfg)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Watch() = %v", err)
+	}
+	defer stop()
+
+	// Touch a second fragment under config.d, which watchPaths already
+	// watches; reload must re-run LoadDir so the 10-override.yaml
+	// fragment is still applied, not just the unmerged base.
+	if err := ioutil.WriteFile(filepath.Join(dir, "config.d", "20-other.yaml"), []byte("value: 99\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-changed:
+		if got.Value != 99 {
+			t.Errorf("reloaded config = %+v, want Value:99 (drop-in override preserved)", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("onChange was never called after a config.d fragment changed")
+	}
+}